@@ -0,0 +1,179 @@
+//go:build windows
+
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/kmahyyg/go-rawcopy/pkg/rawcopy"
+)
+
+var (
+	inFile  = flag.String("in", "", "input file")
+	outFile = flag.String("out", "", "output file")
+
+	bulkMode     = flag.Bool("mft-dump", false, "bulk mode: scan $MFT instead of resolving a single path")
+	listOnly     = flag.Bool("list-only", false, "with -mft-dump, only emit an inventory, do not extract data")
+	pathFilter   = flag.String("filter", "", "regexp matched against each record's full path; empty matches everything")
+	inUseOnly    = flag.Bool("in-use-only", true, "with -mft-dump, skip MFT records that are not in-use / are directories")
+	inventoryFmt = flag.String("inventory-format", "csv", "inventory output format for -list-only: csv or json")
+
+	adsFlag    = flag.String("ads", string(rawcopy.ADSModeSidecar), "how to handle alternate data streams: skip|sidecar|native")
+	formatFlag = flag.String("format", string(rawcopy.FormatRaw), "output format: raw|backup (BackupRead-style WIN32_STREAM_ID container)")
+	bufSize    = flag.Int("bufsize", rawcopy.DefaultBufferSize, "copy buffer size in bytes, should be a multiple of the NTFS cluster size")
+
+	SoftVersion string = ""
+)
+
+func init() {
+	flag.Parse()
+	log.SetFlags(log.LstdFlags | log.Lmicroseconds | log.Lshortfile)
+}
+
+func main() {
+	log.Println("go-rawcopy by kmahyyg (2022) - " + SoftVersion)
+	npath := rawcopy.EnsureNTFSPath(*inFile)
+
+	vol, err := rawcopy.OpenVolume(npath[0])
+	if err != nil {
+		log.Fatalln(err)
+	}
+	defer vol.Close()
+
+	if *bulkMode {
+		if err := runBulkMode(vol); err != nil {
+			log.Fatalln(err)
+		}
+		return
+	}
+
+	if err := retrieveSingleFile(vol, npath); err != nil {
+		log.Fatalln(err)
+	}
+}
+
+// retrieveSingleFile is the original go-rawcopy workflow: resolve one path
+// off the volume, print its metadata, and extract its data stream(s).
+func retrieveSingleFile(vol *rawcopy.Volume, npath []string) error {
+	npathRela := rawcopy.ToNTFSRelativePath(npath[1:])
+
+	log.Println("Try to find file MFT_Entry Location.")
+	f, err := vol.Open(npathRela)
+	if err != nil {
+		return err
+	}
+
+	meta := f.Stat()
+	log.Printf(`
+    File Path: %s
+    File CTime: %s
+    File MTime: %s
+    MFT MTime: %s
+    File ATime: %s
+    Size: %d
+    ADS: %s
+`, meta.FullPath, meta.CTime, meta.MTime, meta.MFTMTime, meta.ATime, meta.Size, formatStreamSummary(meta.Streams))
+
+	mode := rawcopy.ADSMode(*adsFlag)
+	format := rawcopy.OutputFormat(*formatFlag)
+	log.Println("Well, let's start copy now.")
+	opts := rawcopy.ExtractOptions{ADSMode: mode, Format: format, RestoreMetadata: true, BufferSize: *bufSize}
+	if err := f.ExtractTo(*outFile, opts); err != nil {
+		return err
+	}
+
+	log.Println("Workload successfully finished.")
+	return nil
+}
+
+func formatStreamSummary(streams []rawcopy.Stream) string {
+	var parts []string
+	for _, s := range streams {
+		if s.Name == "" {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s (%d bytes)", s.Name, s.Size))
+	}
+	if len(parts) == 0 {
+		return "(none)"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// runBulkMode implements -mft-dump: scan $MFT and either extract every
+// match into a mirrored tree under *outFile, or write a CSV/JSON inventory
+// to *outFile with -list-only.
+func runBulkMode(vol *rawcopy.Volume) error {
+	var matcher *regexp.Regexp
+	if *pathFilter != "" {
+		var err error
+		matcher, err = regexp.Compile(*pathFilter)
+		if err != nil {
+			return err
+		}
+	}
+
+	mode := rawcopy.ADSMode(*adsFlag)
+	records, err := vol.ScanMFT(rawcopy.ScanOptions{
+		PathFilter: matcher,
+		InUseOnly:  *inUseOnly,
+		ListOnly:   *listOnly,
+		OutDir:     *outFile,
+		Extract:    rawcopy.ExtractOptions{ADSMode: mode, BufferSize: *bufSize},
+	})
+	if err != nil {
+		return err
+	}
+
+	if !*listOnly {
+		log.Println("Bulk extraction finished.")
+		return nil
+	}
+	return writeInventory(records, *outFile)
+}
+
+func writeInventory(records []rawcopy.InventoryRecord, dst string) error {
+	fd, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+
+	switch strings.ToLower(*inventoryFmt) {
+	case "json":
+		enc := json.NewEncoder(fd)
+		enc.SetIndent("", "  ")
+		return enc.Encode(records)
+	case "csv":
+		w := csv.NewWriter(fd)
+		defer w.Flush()
+		if err := w.Write([]string{"path", "size", "ctime", "mtime", "atime", "mft_record_idx", "resident", "attr_flags"}); err != nil {
+			return err
+		}
+		for _, r := range records {
+			if err := w.Write([]string{
+				r.Path,
+				strconv.FormatInt(r.Size, 10),
+				r.CTime,
+				r.MTime,
+				r.ATime,
+				strconv.FormatUint(r.MFTRecordIdx, 10),
+				strconv.FormatBool(r.Resident),
+				r.AttrFlags,
+			}); err != nil {
+				return err
+			}
+		}
+		return w.Error()
+	default:
+		return rawcopy.ErrInvalidInput
+	}
+}
@@ -0,0 +1,33 @@
+package wof
+
+// testBitWriter is the encoder-side mirror of bitReader16/msbBitReader: it
+// packs bits MSB-first into 16-bit little-endian words, exactly the layout
+// RtlCompressBufferEx produces and the two bit readers in this package
+// consume. It exists only to build known-good fixtures for the decoder
+// tests in this package; there is no production encoder in this repo.
+type testBitWriter struct {
+	buf   []byte
+	acc   uint32
+	nbits uint
+}
+
+func (w *testBitWriter) writeBits(v uint32, n int) {
+	if n == 0 {
+		return
+	}
+	w.acc = (w.acc << uint(n)) | (v & ((1 << uint(n)) - 1))
+	w.nbits += uint(n)
+	for w.nbits >= 16 {
+		w.nbits -= 16
+		word := uint16((w.acc >> w.nbits) & 0xFFFF)
+		w.buf = append(w.buf, byte(word), byte(word>>8))
+	}
+}
+
+// flush pads any partial word with zero bits and emits it, matching how a
+// real encoder pads block headers out to a 16-bit boundary.
+func (w *testBitWriter) flush() {
+	if w.nbits > 0 {
+		w.writeBits(0, int(16-w.nbits))
+	}
+}
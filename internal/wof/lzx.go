@@ -0,0 +1,358 @@
+package wof
+
+// LZX decompression for WofCompressedData chunks. WOF always configures
+// RtlCompressBuffer/RtlDecompressBufferEx with a 32KB window and, unlike the
+// .cab/.wim container formats, does NOT apply the x86 call-address (E8)
+// translation pass, so this decoder intentionally skips that step.
+//
+// ref: https://www.microsoft.com/en-us/download/details.aspx?id=34633 (MS-PATENT / [MS-LZXCA] community notes)
+// ref: cabextract's lzx.c was used as a structural reference for block layout.
+
+const (
+	lzxMinMatch         = 2
+	lzxNumChars         = 256
+	lzxPretreeSize      = 20
+	lzxAlignedBits      = 3
+	lzxAlignedSize      = 1 << lzxAlignedBits
+	lzxWindowBits       = 15 // 32KB window as configured for WOF
+	lzxNumPositionSlots = 30 // enough for a 32KB window
+)
+
+type lzxBlockType int
+
+const (
+	lzxBlockVerbatim     lzxBlockType = 1
+	lzxBlockAligned      lzxBlockType = 2
+	lzxBlockUncompressed lzxBlockType = 3
+)
+
+// lzxDecompress decodes a single WOF chunk. uncompressedSize is the chunk's
+// logical (decompressed) size; decoding stops once that many bytes are
+// produced. The main-tree size (numMainElements) is fixed by the 32KB-window
+// position-slot count, not derived from uncompressedSize.
+func lzxDecompress(src []byte, uncompressedSize int) ([]byte, error) {
+	br := &msbBitReader{buf: src}
+	window := make([]byte, 0, uncompressedSize)
+
+	numPosSlots := lzxNumPositionSlots
+	numMainElements := lzxNumChars + numPosSlots*8
+
+	mainLens := make([]uint8, numMainElements)
+	lengthLens := make([]uint8, 249)
+
+	var mainTree, lengthTree, alignedTree *huffmanDecoder
+
+	for len(window) < uncompressedSize {
+		blockTypeBits, err := br.readBits(3)
+		if err != nil {
+			return nil, err
+		}
+		blockType := lzxBlockType(blockTypeBits)
+
+		blockLen, err := br.readBits(24)
+		if err != nil {
+			return nil, err
+		}
+
+		switch blockType {
+		case lzxBlockAligned:
+			alignedLens := make([]uint8, lzxAlignedSize)
+			for i := range alignedLens {
+				v, err := br.readBits(3)
+				if err != nil {
+					return nil, err
+				}
+				alignedLens[i] = uint8(v)
+			}
+			alignedTree, err = newHuffmanDecoder(alignedLens)
+			if err != nil {
+				return nil, err
+			}
+			fallthrough
+		case lzxBlockVerbatim:
+			if err := readLZXTree(br, mainLens[:lzxNumChars]); err != nil {
+				return nil, err
+			}
+			if err := readLZXTree(br, mainLens[lzxNumChars:]); err != nil {
+				return nil, err
+			}
+			mainTree, err = newHuffmanDecoder(mainLens)
+			if err != nil {
+				return nil, err
+			}
+			if err := readLZXTree(br, lengthLens); err != nil {
+				return nil, err
+			}
+			lengthTree, err = newHuffmanDecoder(lengthLens)
+			if err != nil {
+				return nil, err
+			}
+
+			target := len(window) + blockLen
+			if target > uncompressedSize {
+				target = uncompressedSize
+			}
+			if err := lzxDecodeBlock(br, mainTree, lengthTree, alignedTree, blockType, &window, target); err != nil {
+				return nil, err
+			}
+
+		case lzxBlockUncompressed:
+			br.align16()
+			if br.bytePos()+blockLen > len(src) {
+				return nil, ErrCorruptStream
+			}
+			raw := src[br.bytePos() : br.bytePos()+blockLen]
+			window = append(window, raw...)
+			br.skipBytes(blockLen)
+
+		default:
+			return nil, ErrCorruptStream
+		}
+	}
+
+	if len(window) > uncompressedSize {
+		window = window[:uncompressedSize]
+	}
+	return window, nil
+}
+
+// readLZXTree decodes one Huffman-coded code-length table, itself encoded
+// via the 20-symbol pretree plus the usual "repeat previous/zero" run
+// encoding used throughout LZX.
+func readLZXTree(br *msbBitReader, lens []uint8) error {
+	preLens := make([]uint8, lzxPretreeSize)
+	for i := range preLens {
+		v, err := br.readBits(4)
+		if err != nil {
+			return err
+		}
+		preLens[i] = uint8(v)
+	}
+	preTree, err := newHuffmanDecoder(preLens)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < len(lens); {
+		sym, err := preTree.decodeMSB(br)
+		if err != nil {
+			return err
+		}
+		switch {
+		case sym == 17: // zero run, short
+			n, err := br.readBits(4)
+			if err != nil {
+				return err
+			}
+			for j := 0; j < n+4 && i < len(lens); j++ {
+				lens[i] = 0
+				i++
+			}
+		case sym == 18: // zero run, long
+			n, err := br.readBits(5)
+			if err != nil {
+				return err
+			}
+			for j := 0; j < n+20 && i < len(lens); j++ {
+				lens[i] = 0
+				i++
+			}
+		case sym == 19: // run of 4-5 copies of one delta-coded length
+			n, err := br.readBits(1)
+			if err != nil {
+				return err
+			}
+			count := n + 4
+			repSym, err := preTree.decodeMSB(br)
+			if err != nil {
+				return err
+			}
+			delta := int(lens[i]) - int(repSym)
+			if delta < 0 {
+				delta += 17
+			}
+			for j := 0; j < count && i < len(lens); j++ {
+				lens[i] = uint8(delta)
+				i++
+			}
+		default:
+			delta := int(lens[i]) - sym
+			if delta < 0 {
+				delta += 17
+			}
+			lens[i] = uint8(delta)
+			i++
+		}
+	}
+	return nil
+}
+
+func lzxDecodeBlock(br *msbBitReader, mainTree, lengthTree, alignedTree *huffmanDecoder, bt lzxBlockType, window *[]byte, target int) error {
+	r := [3]int{1, 1, 1} // repeated-match distance cache
+
+	for len(*window) < target {
+		sym, err := mainTree.decodeMSB(br)
+		if err != nil {
+			return err
+		}
+		if sym < lzxNumChars {
+			*window = append(*window, byte(sym))
+			continue
+		}
+
+		posSlot := (sym - lzxNumChars) >> 3
+		lenHeader := (sym - lzxNumChars) & 7
+
+		length := lenHeader + lzxMinMatch
+		if lenHeader == 7 {
+			extra, err := lengthTree.decodeMSB(br)
+			if err != nil {
+				return err
+			}
+			length = extra + 7 + lzxMinMatch
+		}
+
+		var distance int
+		switch posSlot {
+		case 0:
+			distance = r[0]
+		case 1:
+			distance = r[1]
+			r[1], r[0] = r[0], r[1]
+		case 2:
+			distance = r[2]
+			r[2], r[0] = r[0], r[2]
+		default:
+			footerBits := lzxFooterBits(posSlot)
+			base := lzxPositionBase(posSlot)
+			var verbatimBits int
+			if bt == lzxBlockAligned && footerBits >= 3 {
+				hi, err := br.readBits(footerBits - 3)
+				if err != nil {
+					return err
+				}
+				lo, err := alignedTree.decodeMSB(br)
+				if err != nil {
+					return err
+				}
+				verbatimBits = hi<<3 | lo
+			} else {
+				v, err := br.readBits(footerBits)
+				if err != nil {
+					return err
+				}
+				verbatimBits = v
+			}
+			distance = base + verbatimBits - 2
+			r[2], r[1], r[0] = r[1], r[0], distance
+		}
+
+		if distance <= 0 || distance > len(*window) {
+			return ErrCorruptStream
+		}
+		copyStart := len(*window) - distance
+		for i := 0; i < length && len(*window) < target; i++ {
+			*window = append(*window, (*window)[copyStart+i])
+		}
+	}
+	return nil
+}
+
+// lzxPositionBase/lzxFooterBits implement the standard LZX position-slot
+// table for a 32KB window (slots 0..29 suffice).
+var lzxPosBase = func() [lzxNumPositionSlots]int {
+	var base [lzxNumPositionSlots]int
+	footer := []int{0, 0, 0, 0, 1, 1, 2, 2, 3, 3, 4, 4, 5, 5, 6, 6, 7, 7, 8, 8, 9, 9, 10, 10, 11, 11, 12, 12, 13, 13}
+	b := 0
+	for i := 0; i < lzxNumPositionSlots; i++ {
+		base[i] = b
+		b += 1 << footer[i]
+	}
+	return base
+}()
+
+var lzxFooter = [lzxNumPositionSlots]int{0, 0, 0, 0, 1, 1, 2, 2, 3, 3, 4, 4, 5, 5, 6, 6, 7, 7, 8, 8, 9, 9, 10, 10, 11, 11, 12, 12, 13, 13}
+
+func lzxPositionBase(slot int) int { return lzxPosBase[slot] }
+func lzxFooterBits(slot int) int   { return lzxFooter[slot] }
+
+// msbBitReader reads MSB-first bits packed 16 at a time, little-endian per
+// 16-bit unit, which is how LZX (unlike plain Xpress) bit-packs its stream.
+type msbBitReader struct {
+	buf     []byte
+	pos     int
+	current uint32
+	nbits   uint
+}
+
+// fill tops the bit buffer back up to 16 bits. Once the source is exhausted
+// it pads with implicit zero bits rather than erroring: a prefix code's last
+// symbol is allowed to end flush with the final byte, so peeking the full
+// maxCodeLen window past it is normal, not corruption. decodeMSB still
+// catches genuine corruption via its zero-length table-entry check.
+func (b *msbBitReader) fill() error {
+	for b.nbits < 16 {
+		var word uint32
+		switch {
+		case b.pos+2 <= len(b.buf):
+			word = uint32(b.buf[b.pos]) | uint32(b.buf[b.pos+1])<<8
+			b.pos += 2
+		case b.pos+1 == len(b.buf):
+			word = uint32(b.buf[b.pos])
+			b.pos++
+		}
+		b.current = (b.current << 16) | word
+		b.nbits += 16
+	}
+	return nil
+}
+
+func (b *msbBitReader) readBits(n int) (int, error) {
+	if n == 0 {
+		return 0, nil
+	}
+	b.fill()
+	shift := b.nbits - uint(n)
+	val := (b.current >> shift) & ((1 << uint(n)) - 1)
+	b.nbits -= uint(n)
+	return int(val), nil
+}
+
+func (b *msbBitReader) peekBits(n int) (int, error) {
+	b.fill()
+	shift := b.nbits - uint(n)
+	return int((b.current >> shift) & ((1 << uint(n)) - 1)), nil
+}
+
+func (b *msbBitReader) dropBits(n int) { b.nbits -= uint(n) }
+
+func (b *msbBitReader) align16() {
+	b.nbits -= b.nbits % 16
+}
+
+func (b *msbBitReader) bytePos() int {
+	return b.pos - int(b.nbits/8)
+}
+
+func (b *msbBitReader) skipBytes(n int) {
+	b.pos += n
+	b.current = 0
+	b.nbits = 0
+	if n%2 != 0 {
+		b.pos++ // uncompressed blocks are padded back to a 16-bit boundary
+	}
+}
+
+func (d *huffmanDecoder) decodeMSB(br *msbBitReader) (int, error) {
+	idx, err := br.peekBits(maxCodeLen)
+	if err != nil {
+		return 0, err
+	}
+	entry := d.table[idx]
+	length := int(entry >> 12)
+	if length == 0 {
+		return 0, ErrCorruptStream
+	}
+	br.dropBits(length)
+	return int(entry & 0x0FFF), nil
+}
@@ -0,0 +1,66 @@
+package wof
+
+import (
+	"bytes"
+	"testing"
+)
+
+// uniformMainTree returns a mainTree where every symbol (0..numMainElements)
+// has the same code length, so canonical Huffman assigns codes 0..n-1 in
+// symbol order and a symbol's code equals its numeric value - the same
+// trick literalOnlyTable uses in xpress_test.go.
+func uniformMainTree(t *testing.T, n int, length uint8) *huffmanDecoder {
+	t.Helper()
+	lens := make([]uint8, n)
+	for i := range lens {
+		lens[i] = length
+	}
+	dec, err := newHuffmanDecoder(lens)
+	if err != nil {
+		t.Fatalf("newHuffmanDecoder: %v", err)
+	}
+	return dec
+}
+
+// TestLZXDecodeBlock_RepeatOffsetCache exercises the match path of
+// lzxDecodeBlock with posSlot 1 and 2 (R1/R2 reuse), which
+// TestLZXDecompress_UncompressedBlock never touches. It drives three "new
+// distance" matches to seed r0/r1/r2 with three distinct, well-separated
+// values, then an R1 reuse and an R2 reuse, then a final R1 reuse whose
+// distance is only correct if the prior two reuses actually rotated the
+// cache per the LZX spec (posSlot 1 swaps r0<->r1, posSlot 2 swaps
+// r0<->r2) rather than leaving it untouched.
+func TestLZXDecodeBlock_RepeatOffsetCache(t *testing.T) {
+	const numMainElements = lzxNumChars + lzxNumPositionSlots*8
+	mainTree := uniformMainTree(t, numMainElements, 9)
+
+	matchSym := func(posSlot, lenHeader int) uint32 {
+		return uint32(lzxNumChars + posSlot*8 + lenHeader)
+	}
+
+	bw := &testBitWriter{}
+	for _, c := range []byte("ABCDEFGHIJ") {
+		bw.writeBits(uint32(c), 9)
+	}
+	bw.writeBits(matchSym(6, 0), 9) // new distance: base6(8)+0-2 = 6
+	bw.writeBits(0, 2)
+	bw.writeBits(matchSym(6, 0), 9) // new distance: base6(8)+3-2 = 9
+	bw.writeBits(3, 2)
+	bw.writeBits(matchSym(7, 0), 9) // new distance: base7(12)+3-2 = 13
+	bw.writeBits(3, 2)
+	bw.writeBits(matchSym(1, 0), 9) // R1 reuse: distance 9 either way
+	bw.writeBits(matchSym(2, 0), 9) // R2 reuse: distance 6 either way
+	bw.writeBits(matchSym(1, 0), 9) // R1 reuse again: 13 if rotated, 9 if not
+	bw.flush()
+
+	br := &msbBitReader{buf: bw.buf}
+	var window []byte
+	if err := lzxDecodeBlock(br, mainTree, nil, nil, lzxBlockVerbatim, &window, 22); err != nil {
+		t.Fatalf("lzxDecodeBlock: %v", err)
+	}
+
+	want := []byte("ABCDEFGHIJEFDEBCHIDEHI")
+	if !bytes.Equal(window, want) {
+		t.Fatalf("got %q, want %q", window, want)
+	}
+}
@@ -0,0 +1,32 @@
+package wof
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestLZXDecompress_UncompressedBlock exercises the lzxBlockUncompressed path
+// end-to-end, since it needs no Huffman tables and is by far the easiest
+// block type to hand-build a fixture for. It only proves self-consistency
+// between lzxDecompress and the msbBitReader/testBitWriter pairing (see
+// bitwriter_test.go), not
+// interop against a real RtlCompressBuffer-produced LZX stream - there is no
+// reference encoder available in this repo to validate against.
+func TestLZXDecompress_UncompressedBlock(t *testing.T) {
+	want := []byte("TEST")
+
+	bw := &testBitWriter{}
+	bw.writeBits(uint32(lzxBlockUncompressed), 3)
+	bw.writeBits(uint32(len(want)), 24)
+	bw.flush() // pads to the 16-bit boundary align16() expects before raw bytes
+
+	src := append(bw.buf, want...)
+
+	got, err := lzxDecompress(src, len(want))
+	if err != nil {
+		t.Fatalf("lzxDecompress: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
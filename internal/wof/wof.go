@@ -0,0 +1,127 @@
+package wof
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// IO_REPARSE_TAG_WOF identifies a reparse point whose real data lives in a
+// WofCompressedData alternate data stream rather than the primary $DATA
+// attribute.
+const IOReparseTagWOF = 0x80000017
+
+// Algorithm identifies which decompressor a WofCompressedData stream was
+// produced with, as recorded in the WOF_EXTERNAL_INFO / FILE_PROVIDER_EXTERNAL_INFO_V1 header.
+type Algorithm uint32
+
+const (
+	AlgorithmXPRESS4K  Algorithm = 0
+	AlgorithmLZX       Algorithm = 1
+	AlgorithmXPRESS8K  Algorithm = 2
+	AlgorithmXPRESS16K Algorithm = 3
+)
+
+func (a Algorithm) ChunkSize() int {
+	switch a {
+	case AlgorithmXPRESS4K:
+		return 4096
+	case AlgorithmXPRESS8K:
+		return 8192
+	case AlgorithmXPRESS16K:
+		return 16384
+	case AlgorithmLZX:
+		return 32768
+	default:
+		return 0
+	}
+}
+
+func (a Algorithm) String() string {
+	switch a {
+	case AlgorithmXPRESS4K:
+		return "XPRESS4K"
+	case AlgorithmXPRESS8K:
+		return "XPRESS8K"
+	case AlgorithmXPRESS16K:
+		return "XPRESS16K"
+	case AlgorithmLZX:
+		return "LZX"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+var ErrUnsupportedAlgorithm = errors.New("wof: unsupported compression algorithm")
+
+// ReparseInfo is the subset of a parsed WOF reparse buffer we need to read
+// WofCompressedData back out.
+type ReparseInfo struct {
+	Algorithm        Algorithm
+	UncompressedSize int64
+}
+
+// ParseReparseBuffer parses the WOF_EXTERNAL_INFO ("FILE_PROVIDER" version 1)
+// structure embedded in a $REPARSE_POINT attribute tagged
+// IO_REPARSE_TAG_WOF. Layout (little-endian):
+//
+//	uint32 Version       (expected 1)
+//	uint32 Provider      (1 == WOF_PROVIDER_WIM, 2 == WOF_PROVIDER_FILE)
+//	uint32 AlgorithmId    (one of the Algorithm* constants, FILE provider only)
+//	uint32 Flags
+//
+// The reparse data buffer's generic reparse header (tag/length/reserved) is
+// expected to already have been stripped off by the caller.
+func ParseReparseBuffer(buf []byte) (*ReparseInfo, error) {
+	if len(buf) < 16 {
+		return nil, errors.New("wof: reparse buffer too short")
+	}
+	version := binary.LittleEndian.Uint32(buf[0:4])
+	provider := binary.LittleEndian.Uint32(buf[4:8])
+	if version != 1 || provider != 2 { // 2 == WOF_PROVIDER_FILE
+		return nil, ErrUnsupportedAlgorithm
+	}
+	algo := Algorithm(binary.LittleEndian.Uint32(buf[8:12]))
+	return &ReparseInfo{Algorithm: algo}, nil
+}
+
+// ChunkTable describes the boundaries of each compressed chunk inside a
+// WofCompressedData stream, as derived from its leading offset table.
+type ChunkTable struct {
+	// Offsets[i] is the start offset of chunk i within the stream (payload
+	// immediately follows the table); Offsets[numChunks] is the stream's
+	// total compressed length.
+	Offsets []int64
+}
+
+// ParseChunkTable returns how many chunks a WofCompressedData stream
+// compressed with the given algorithm and known uncompressed size holds,
+// i.e. ceil(uncompressedSize / chunk size); it returns 0 for an unknown
+// algorithm. It does not read the table itself and does not use streamLen.
+// The table holds numChunks-1 uint32 (or uint64 for LZX >4GB chunks, not
+// supported here) little-endian offsets measured from the end of the
+// table itself; chunk 0 always starts right after it.
+func ParseChunkTable(streamLen int64, uncompressedSize int64, algo Algorithm) (numChunks int) {
+	chunkSize := int64(algo.ChunkSize())
+	if chunkSize == 0 {
+		return 0
+	}
+	numChunks = int((uncompressedSize + chunkSize - 1) / chunkSize)
+	return numChunks
+}
+
+// DecompressChunk decompresses a single chunk. If the chunk is stored
+// uncompressed (compressed length == chunk's logical size), it is returned
+// unchanged.
+func DecompressChunk(algo Algorithm, compressed []byte, uncompressedChunkSize int) ([]byte, error) {
+	if len(compressed) == uncompressedChunkSize {
+		return compressed, nil
+	}
+	switch algo {
+	case AlgorithmXPRESS4K, AlgorithmXPRESS8K, AlgorithmXPRESS16K:
+		return xpressHuffmanDecompress(compressed, uncompressedChunkSize)
+	case AlgorithmLZX:
+		return lzxDecompress(compressed, uncompressedChunkSize)
+	default:
+		return nil, ErrUnsupportedAlgorithm
+	}
+}
@@ -0,0 +1,217 @@
+// Package wof implements the chunk-level decompressors needed to read back
+// files that Windows stored via the Windows Overlay Filter (WOF), i.e.
+// anything touched by `compact.exe /EXE`. WOF itself is just a container
+// (see wof.go); the payload inside each chunk is plain XPRESS, XPRESS
+// Huffman or LZX as produced by the RtlCompressBuffer family of APIs.
+package wof
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// ErrCorruptStream is returned when a compressed chunk does not parse
+// according to the expected bitstream layout (truncated table, window
+// overrun, etc).
+var ErrCorruptStream = errors.New("wof: corrupt compressed chunk")
+
+// xpressHuffmanDecompress decodes an MS-XCA "Xpress Huffman" compressed chunk as used
+// by WofCompressedData for the XPRESS4K/8K/16K algorithms. The stream is a
+// 256-byte prefix-length table (two 4-bit lengths per byte, 512 symbols)
+// followed by the Huffman-coded token stream, 16 bits read little-endian at
+// a time, MSB-first within each 16-bit unit.
+//
+// ref: https://winprotocoldoc.blob.core.windows.net/productionwindowsarchives/MS-XCA/%5bMS-XCA%5d.pdf
+func xpressHuffmanDecompress(src []byte, uncompressedSize int) ([]byte, error) {
+	if len(src) < 256 {
+		return nil, ErrCorruptStream
+	}
+
+	lengths := make([]uint8, 512)
+	for i := 0; i < 256; i++ {
+		lengths[2*i] = src[i] & 0xF
+		lengths[2*i+1] = src[i] >> 4
+	}
+
+	dec, err := newHuffmanDecoder(lengths)
+	if err != nil {
+		return nil, err
+	}
+
+	br := &bitReader16{buf: src[256:]}
+	out := make([]byte, 0, uncompressedSize)
+
+	for len(out) < uncompressedSize {
+		sym, err := dec.decodeSymbol(br)
+		if err != nil {
+			return nil, err
+		}
+
+		if sym < 256 {
+			out = append(out, byte(sym))
+			continue
+		}
+
+		// match token: high nibble of (sym-256) is the distance bit-count,
+		// low nibble is the length base (0..15, 15 means "read more").
+		v := sym - 256
+		distBits := v >> 4
+		length := int(v & 0xF)
+
+		if length == 15 {
+			extra, err := br.readByte()
+			if err != nil {
+				return nil, err
+			}
+			length += int(extra)
+			if extra == 0xFF {
+				lo, err := br.readUint16LE()
+				if err != nil {
+					return nil, err
+				}
+				length = int(lo)
+				// a 0xFFFF length field replaces, rather than adds to, the running total
+			}
+		}
+		length += 3
+
+		var distance int
+		if distBits == 0 {
+			distance = 1
+		} else {
+			extraBits, err := br.readBits(int(distBits))
+			if err != nil {
+				return nil, err
+			}
+			distance = (1 << distBits) + extraBits
+		}
+
+		if distance > len(out) {
+			return nil, ErrCorruptStream
+		}
+		copyStart := len(out) - distance
+		for i := 0; i < length && len(out) < uncompressedSize; i++ {
+			out = append(out, out[copyStart+i])
+		}
+	}
+
+	return out, nil
+}
+
+// bitReader16 pulls bits MSB-first out of a byte slice two bytes
+// (one little-endian uint16) at a time, matching RtlDecompressBufferEx's
+// bit-packing for Xpress Huffman.
+type bitReader16 struct {
+	buf     []byte
+	pos     int
+	current uint32
+	nbits   uint
+}
+
+// fill tops the bit buffer back up to 16 bits. Once the source is exhausted
+// it pads with implicit zero bits rather than erroring: a prefix code's last
+// symbol is allowed to end flush with the final byte, so peeking the full
+// maxCodeLen window past it is normal, not corruption. decodeSymbol still
+// catches genuine corruption via its zero-length table-entry check.
+func (b *bitReader16) fill() error {
+	for b.nbits < 16 {
+		var word uint16
+		switch {
+		case b.pos+2 <= len(b.buf):
+			word = binary.LittleEndian.Uint16(b.buf[b.pos:])
+			b.pos += 2
+		case b.pos+1 == len(b.buf):
+			word = uint16(b.buf[b.pos])
+			b.pos++
+		}
+		b.current = (b.current << 16) | uint32(word)
+		b.nbits += 16
+	}
+	return nil
+}
+
+func (b *bitReader16) readBits(n int) (int, error) {
+	if n == 0 {
+		return 0, nil
+	}
+	b.fill()
+	shift := b.nbits - uint(n)
+	val := (b.current >> shift) & ((1 << uint(n)) - 1)
+	b.nbits -= uint(n)
+	return int(val), nil
+}
+
+func (b *bitReader16) peekBits(n int) (int, error) {
+	b.fill()
+	shift := b.nbits - uint(n)
+	return int((b.current >> shift) & ((1 << uint(n)) - 1)), nil
+}
+
+func (b *bitReader16) dropBits(n int) {
+	b.nbits -= uint(n)
+}
+
+func (b *bitReader16) readByte() (byte, error) {
+	v, err := b.readBits(8)
+	return byte(v), err
+}
+
+func (b *bitReader16) readUint16LE() (uint16, error) {
+	v, err := b.readBits(16)
+	return uint16(v), err
+}
+
+// huffmanDecoder is a canonical Huffman decoder built from a flat array of
+// per-symbol code lengths (0 meaning "unused"), looked up via a
+// max-15-bit flat table the same way RtlDecompressBufferEx does.
+type huffmanDecoder struct {
+	table []uint16 // index: next 15 bits of the stream -> symbol
+}
+
+const maxCodeLen = 15
+
+func newHuffmanDecoder(lengths []uint8) (*huffmanDecoder, error) {
+	var count [maxCodeLen + 1]int
+	for _, l := range lengths {
+		if l > maxCodeLen {
+			return nil, ErrCorruptStream
+		}
+		count[l]++
+	}
+	count[0] = 0
+
+	var code [maxCodeLen + 2]int
+	for i := 1; i <= maxCodeLen; i++ {
+		code[i+1] = (code[i] + count[i]) << 1
+	}
+
+	table := make([]uint16, 1<<maxCodeLen)
+	for sym, l := range lengths {
+		if l == 0 {
+			continue
+		}
+		c := code[l]
+		code[l]++
+		start := c << (maxCodeLen - int(l))
+		span := 1 << (maxCodeLen - int(l))
+		for i := 0; i < span; i++ {
+			table[start+i] = uint16(sym) | uint16(l)<<12
+		}
+	}
+
+	return &huffmanDecoder{table: table}, nil
+}
+
+func (d *huffmanDecoder) decodeSymbol(br *bitReader16) (int, error) {
+	idx, err := br.peekBits(maxCodeLen)
+	if err != nil {
+		return 0, err
+	}
+	entry := d.table[idx]
+	length := int(entry >> 12)
+	if length == 0 {
+		return 0, ErrCorruptStream
+	}
+	br.dropBits(length)
+	return int(entry & 0x0FFF), nil
+}
@@ -0,0 +1,78 @@
+package wof
+
+import (
+	"bytes"
+	"testing"
+)
+
+// literalOnlyTable returns an Xpress Huffman prefix-length table where every
+// one of the 256 literal symbols has code length 8 and every match symbol
+// (256-511) is unused (length 0). With only one length active, canonical
+// Huffman assigns codes 0..255 in symbol order, so an 8-bit code equals the
+// literal byte value itself - which makes it easy to hand-build a stream.
+func literalOnlyTable() []byte {
+	table := make([]byte, 256)
+	for i := 0; i < 128; i++ {
+		table[i] = 0x88 // lengths[2i]=8, lengths[2i+1]=8 (two literal symbols)
+	}
+	// table[128:256] stays 0x00 -> lengths[256:512]=0, match symbols unused
+	return table
+}
+
+func TestXpressHuffmanDecompress_LiteralsOnly(t *testing.T) {
+	want := []byte("Hello, WOF!")
+
+	bw := &testBitWriter{}
+	for _, b := range want {
+		bw.writeBits(uint32(b), 8)
+	}
+	bw.flush()
+
+	src := append(literalOnlyTable(), bw.buf...)
+
+	got, err := xpressHuffmanDecompress(src, len(want))
+	if err != nil {
+		t.Fatalf("xpressHuffmanDecompress: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestXpressHuffmanDecompress_TruncatedTable(t *testing.T) {
+	_, err := xpressHuffmanDecompress(make([]byte, 10), 100)
+	if err != ErrCorruptStream {
+		t.Fatalf("expected ErrCorruptStream, got %v", err)
+	}
+}
+
+func TestHuffmanDecoder_RoundTrip(t *testing.T) {
+	// A small, intentionally unbalanced code: symbol 0 gets the 1-bit code,
+	// symbols 1 and 2 get 2-bit codes - the classic 3-symbol example.
+	lengths := make([]uint8, 512)
+	lengths[0] = 1
+	lengths[1] = 2
+	lengths[2] = 2
+
+	dec, err := newHuffmanDecoder(lengths)
+	if err != nil {
+		t.Fatalf("newHuffmanDecoder: %v", err)
+	}
+
+	bw := &testBitWriter{}
+	bw.writeBits(0, 1)    // symbol 0, code "0"
+	bw.writeBits(0b10, 2) // symbol 1, code "10"
+	bw.writeBits(0b11, 2) // symbol 2, code "11"
+	bw.flush()
+
+	br := &bitReader16{buf: bw.buf}
+	for _, want := range []int{0, 1, 2} {
+		got, err := dec.decodeSymbol(br)
+		if err != nil {
+			t.Fatalf("decodeSymbol: %v", err)
+		}
+		if got != want {
+			t.Fatalf("decodeSymbol() = %d, want %d", got, want)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+//go:build windows
+
+package rawcopy
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"log"
+	"os"
+	"unicode/utf16"
+
+	ntfs "www.velocidex.com/golang/go-ntfs/parser"
+)
+
+// Win32 backup stream IDs, as consumed by BackupRead/BackupWrite and
+// documented for the WIN32_STREAM_ID structure.
+const (
+	backupData          uint32 = 1
+	backupSecurityData  uint32 = 3
+	backupAlternateData uint32 = 4
+	backupReparseData   uint32 = 8
+)
+
+const NTFSAttrType_SecurityDescriptor = 80
+
+// $Secure is the well-known system file (MFT record 9) holding the
+// volume-wide security descriptor store; its unnamed $SDS data stream is a
+// sequence of SECURITY_DESCRIPTOR_HEADER entries (hash, security id,
+// absolute offset, entry size, then the descriptor bytes) that
+// STANDARD_INFORMATION.Sid() indexes into.
+const (
+	ntfsSecureMFTRecord = 9
+	sdsEntryHeaderSize  = 20
+)
+
+// writeBackupStream writes one WIN32_STREAM_ID record: a 20-byte header
+// (StreamId, StreamAttributes, Size as a 64-bit value split across two
+// uint32s, NameSize) followed by the optional UTF-16 name and then size
+// bytes copied from r in backupStreamBufSize-sized chunks, so a multi-GB
+// stream is never held in memory whole.
+//
+// ref: https://learn.microsoft.com/en-us/windows/win32/api/winbase/ns-winbase-win32_stream_id
+func writeBackupStream(w io.Writer, streamID uint32, name string, r io.ReaderAt, size int64) error {
+	var nameUTF16 []uint16
+	if name != "" {
+		nameUTF16 = utf16.Encode([]rune(name))
+	}
+	nameSize := uint32(len(nameUTF16) * 2)
+
+	header := make([]byte, 20)
+	binary.LittleEndian.PutUint32(header[0:4], streamID)
+	binary.LittleEndian.PutUint32(header[4:8], 0) // StreamAttributes, none of the WOF/sparse/encrypted flags apply here
+	binary.LittleEndian.PutUint64(header[8:16], uint64(size))
+	binary.LittleEndian.PutUint32(header[16:20], nameSize)
+
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	if nameSize > 0 {
+		nameBytes := make([]byte, nameSize)
+		for i, u := range nameUTF16 {
+			binary.LittleEndian.PutUint16(nameBytes[2*i:], u)
+		}
+		if _, err := w.Write(nameBytes); err != nil {
+			return err
+		}
+	}
+
+	buf := make([]byte, backupStreamBufSize)
+	// Hide any ReaderFrom on w (e.g. *os.File) so io.CopyBuffer uses buf.
+	_, err := io.CopyBuffer(struct{ io.Writer }{w}, io.NewSectionReader(r, 0, size), buf)
+	return err
+}
+
+// backupStreamBufSize bounds how much of any one stream writeBackupStream
+// holds in memory at a time.
+const backupStreamBufSize = DefaultBufferSize
+
+// writeBackupContainer emits dst as a BackupRead-style stream sequence: one
+// BACKUP_DATA record for the unnamed $DATA, one BACKUP_ALTERNATE_DATA
+// record per ADS, a BACKUP_REPARSE_DATA record when a reparse point is
+// present, and a BACKUP_SECURITY_DATA record with the entry's security
+// descriptor, resolved from a resident $SECURITY_DESCRIPTOR attribute or
+// (the common case on any volume since Windows Vista) from $Secure:$SDS via
+// securityDescriptorFor. This lets the container be fed back via
+// BackupWrite on a target system to round-trip ADS, security and reparse
+// metadata that a plain copy + ApplyOriginalMetadata drops.
+func writeBackupContainer(vol *Volume, entry *ntfs.MFT_ENTRY, dst string) error {
+	fd, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+
+	for _, attr := range entry.EnumerateAttributes(vol.ctx) {
+		if attr.Type().Value != NTFSAttrType_Data {
+			continue
+		}
+		reader, err := ntfs.OpenStream(vol.ctx, entry, NTFSAttrType_Data, attr.Attribute_id())
+		if err != nil {
+			continue
+		}
+		streamID := backupAlternateData
+		if attr.Name() == "" {
+			streamID = backupData
+		}
+		if err := writeBackupStream(fd, streamID, attr.Name(), reader, attr.DataSize()); err != nil {
+			return err
+		}
+	}
+
+	if reparseAttr, ok := findAttribute(vol, entry, NTFSAttrType_ReparsePoint, NTFSAttrID_Normal); ok {
+		if reparseReader, err := ntfs.OpenStream(vol.ctx, entry, NTFSAttrType_ReparsePoint, NTFSAttrID_Normal); err == nil {
+			if err := writeBackupStream(fd, backupReparseData, "", reparseReader, reparseAttr.DataSize()); err != nil {
+				return err
+			}
+		}
+	}
+
+	secBytes, err := securityDescriptorFor(vol, entry)
+	if err != nil {
+		log.Printf("writeBackupContainer: %s: security descriptor unavailable: %v\n", dst, err)
+	} else if err := writeBackupStream(fd, backupSecurityData, "", bytes.NewReader(secBytes), int64(len(secBytes))); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// securityDescriptorFor returns entry's security descriptor bytes, preferring
+// a resident $SECURITY_DESCRIPTOR attribute (the pre-Vista layout) and
+// falling back to a $Secure:$SDS lookup keyed by
+// STANDARD_INFORMATION.Sid(), which is how virtually every file stores its
+// security descriptor on modern NTFS.
+func securityDescriptorFor(vol *Volume, entry *ntfs.MFT_ENTRY) ([]byte, error) {
+	if secAttr, ok := findAttribute(vol, entry, NTFSAttrType_SecurityDescriptor, NTFSAttrID_Normal); ok {
+		reader, err := ntfs.OpenStream(vol.ctx, entry, NTFSAttrType_SecurityDescriptor, NTFSAttrID_Normal)
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, secAttr.DataSize())
+		if _, err := reader.ReadAt(buf, 0); err != nil {
+			return nil, err
+		}
+		return buf, nil
+	}
+
+	stdInfo, err := entry.StandardInformation(vol.ctx)
+	if err != nil || stdInfo == nil {
+		return nil, os.ErrNotExist
+	}
+	return lookupSecureSDS(vol, stdInfo.Sid())
+}
+
+// lookupSecureSDS linearly scans $Secure:$SDS for the entry whose security
+// id matches sid, returning its descriptor bytes. $SDS has no companion
+// index lookup here (the $SII/$SDH B+trees aren't parsed by go-ntfs), so
+// this walks entries from the start of the stream; that's acceptable since
+// writeBackupContainer calls it once per file, not once per MFT record
+// scanned.
+func lookupSecureSDS(vol *Volume, sid uint32) ([]byte, error) {
+	secureEntry, err := vol.ctx.GetMFT(ntfsSecureMFTRecord)
+	if err != nil {
+		return nil, err
+	}
+
+	var sdsAttr *ntfs.NTFS_ATTRIBUTE
+	for _, attr := range secureEntry.EnumerateAttributes(vol.ctx) {
+		if attr.Type().Value == NTFSAttrType_Data && attr.Name() == "$SDS" {
+			sdsAttr = attr
+			break
+		}
+	}
+	if sdsAttr == nil {
+		return nil, os.ErrNotExist
+	}
+
+	reader, err := ntfs.OpenStream(vol.ctx, secureEntry, NTFSAttrType_Data, sdsAttr.Attribute_id())
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, sdsEntryHeaderSize)
+	size := sdsAttr.DataSize()
+	for offset := int64(0); offset+sdsEntryHeaderSize <= size; {
+		if _, err := reader.ReadAt(header, offset); err != nil {
+			return nil, err
+		}
+		entrySID := binary.LittleEndian.Uint32(header[4:8])
+		entrySize := binary.LittleEndian.Uint32(header[16:20])
+		if entrySize == 0 {
+			break // padding to the next allocation block; no more entries in this run
+		}
+		if int64(entrySize) < sdsEntryHeaderSize || offset+int64(entrySize) > size {
+			return nil, ErrCorruptData
+		}
+		if entrySID == sid {
+			sd := make([]byte, int64(entrySize)-sdsEntryHeaderSize)
+			if _, err := reader.ReadAt(sd, offset+sdsEntryHeaderSize); err != nil {
+				return nil, err
+			}
+			return sd, nil
+		}
+		offset += int64(entrySize)
+	}
+	return nil, os.ErrNotExist
+}
+
+// findAttribute locates an attribute by type and id on entry, so its
+// DataSize() can be read before opening a stream for it.
+func findAttribute(vol *Volume, entry *ntfs.MFT_ENTRY, attrType uint64, attrID uint16) (*ntfs.NTFS_ATTRIBUTE, bool) {
+	for _, attr := range entry.EnumerateAttributes(vol.ctx) {
+		if attr.Type().Value == attrType && attr.Attribute_id() == attrID {
+			return attr, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,141 @@
+//go:build windows
+
+package rawcopy
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	ntfs "www.velocidex.com/golang/go-ntfs/parser"
+)
+
+// MFTScanLogInterval is how many MFT records Volume.ScanMFT walks between
+// progress log lines.
+const MFTScanLogInterval = 1024
+
+// InventoryRecord is one row of a ScanMFT inventory, mirroring the fields
+// already surfaced by Metadata but flattened for CSV/JSON output.
+type InventoryRecord struct {
+	Path         string
+	Size         int64
+	CTime        string
+	MTime        string
+	ATime        string
+	MFTRecordIdx uint64
+	// Resident reports whether the primary $DATA attribute is stored inline
+	// in the MFT record rather than in external runs.
+	Resident bool
+	// AttrFlags is the primary $DATA attribute's flags (e.g. compressed,
+	// sparse, encrypted), as reported by NTFS_ATTRIBUTE.Flags().DebugString().
+	AttrFlags string
+}
+
+// primaryDataAttr finds the unnamed (primary) $DATA attribute on entry, the
+// one ExtractTo writes when there's no WOF compression and no ADS handling
+// in play.
+func primaryDataAttr(ctx *ntfs.NTFSContext, entry *ntfs.MFT_ENTRY) (*ntfs.NTFS_ATTRIBUTE, bool) {
+	for _, attr := range entry.EnumerateAttributes(ctx) {
+		if attr.Type().Value == NTFSAttrType_Data && attr.Name() == "" {
+			return attr, true
+		}
+	}
+	return nil, false
+}
+
+// ScanOptions configures Volume.ScanMFT.
+type ScanOptions struct {
+	// PathFilter, if non-nil, is matched against each record's full path;
+	// records that don't match are skipped.
+	PathFilter *regexp.Regexp
+	// InUseOnly skips MFT records that are not in-use or that are
+	// directories.
+	InUseOnly bool
+	// ListOnly collects an InventoryRecord per match instead of extracting
+	// data; OutDir is ignored when set.
+	ListOnly bool
+	// OutDir is the root of the mirrored output tree used when ListOnly is
+	// false.
+	OutDir  string
+	Extract ExtractOptions
+}
+
+// ScanMFT walks every record of $MFT via NTFSContext.GetMFT, which already
+// applies the fixup+cache logic the on-disk $MFT stream requires, and
+// parses STANDARD_INFORMATION out of every in-use, non-directory record
+// (every record, when opts.InUseOnly is false). It
+// either extracts matches into a mirrored tree under opts.OutDir or, with
+// opts.ListOnly, returns an inventory without touching file contents. This
+// avoids the per-file Open() directory-index traversal needed to resolve
+// hundreds of paths individually.
+func (v *Volume) ScanMFT(opts ScanOptions) ([]InventoryRecord, error) {
+	mftEntry, err := v.MFTEntry(0)
+	if err != nil {
+		return nil, err
+	}
+
+	mftDataAttr, err := mftEntry.GetAttribute(v.ctx, NTFSAttrType_Data, 0)
+	if err != nil {
+		return nil, err
+	}
+	totalRecords := mftDataAttr.DataSize() / v.ctx.GetRecordSize()
+
+	var inventory []InventoryRecord
+	for recordIdx := int64(0); recordIdx < totalRecords; recordIdx++ {
+		if recordIdx%MFTScanLogInterval == 0 {
+			log.Printf("ScanMFT: walked %d/%d records\n", recordIdx, totalRecords)
+		}
+
+		entry, err := v.ctx.GetMFT(recordIdx)
+		if err != nil {
+			continue // corrupt/unused slack space is common towards the tail of $MFT
+		}
+
+		if opts.InUseOnly && (!entry.Flags().IsSet("ALLOCATED") || entry.IsDir(v.ctx)) {
+			continue
+		}
+
+		stdInfo, err := entry.StandardInformation(v.ctx)
+		if err != nil || stdInfo == nil {
+			continue
+		}
+
+		fullPath := ntfs.GetFullPath(v.ctx, entry)
+		if opts.PathFilter != nil && !opts.PathFilter.MatchString(fullPath) {
+			continue
+		}
+
+		if opts.ListOnly {
+			var resident bool
+			var attrFlags string
+			if dataAttr, ok := primaryDataAttr(v.ctx, entry); ok {
+				resident = dataAttr.IsResident()
+				attrFlags = dataAttr.Flags().DebugString()
+			}
+			inventory = append(inventory, InventoryRecord{
+				Path:         fullPath,
+				Size:         int64(stdInfo.Size()),
+				CTime:        stdInfo.Create_time().String(),
+				MTime:        stdInfo.File_altered_time().String(),
+				ATime:        stdInfo.File_accessed_time().String(),
+				MFTRecordIdx: uint64(recordIdx),
+				Resident:     resident,
+				AttrFlags:    attrFlags,
+			})
+			continue
+		}
+
+		f := &File{vol: v, entry: entry, info: stdInfo, fullPath: fullPath}
+		dstPath := filepath.Join(opts.OutDir, filepath.FromSlash(fullPath))
+		if err := os.MkdirAll(filepath.Dir(dstPath), 0o755); err != nil {
+			log.Printf("ScanMFT: %s: mkdir failed: %v\n", fullPath, err)
+			continue
+		}
+		if err := f.ExtractTo(dstPath, opts.Extract); err != nil {
+			log.Printf("ScanMFT: %s: extract failed: %v\n", fullPath, err)
+		}
+	}
+
+	return inventory, nil
+}
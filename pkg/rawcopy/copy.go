@@ -0,0 +1,197 @@
+//go:build windows
+
+package rawcopy
+
+import (
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"golang.org/x/sys/windows"
+
+	ntfs "www.velocidex.com/golang/go-ntfs/parser"
+)
+
+// progressLogInterval is how often CopyToDestinationFile logs a
+// bytes/MB-per-second/ETA line for long-running copies.
+const progressLogInterval = 2 * time.Second
+
+// CopyToDestinationFile copies src (a file or stream's RangeReaderAt) to
+// dstfile using a bufSize-sized buffer via io.CopyBuffer, which lines up
+// better with pagedReader's cluster-aligned reads than the default 32KB
+// io.Copy buffer. Sparse ranges reported by src.Ranges() are not read at
+// all: the destination is marked sparse (FSCTL_SET_SPARSE) and the range is
+// deallocated directly (FSCTL_SET_ZERO_DATA) to preserve sparseness on NTFS
+// targets; if marking the destination sparse fails, sparse ranges are read
+// and written like any other range. total is the stream's logical size (RangeReaderAt has no Size
+// method of its own, so callers pass what they already know from the
+// attribute/STANDARD_INFORMATION); if progress is non-nil it is invoked
+// after every chunk with the running total and total; a MB/s + ETA summary
+// is logged every progressLogInterval regardless.
+func CopyToDestinationFile(src ntfs.RangeReaderAt, dstfile string, total int64, bufSize int, progress func(written, total int64)) error {
+	if src == nil {
+		return ErrReturnedNil
+	}
+
+	log.Println("Copying to " + dstfile)
+	dstFd, err := os.Create(dstfile)
+	if err != nil {
+		return err
+	}
+	defer dstFd.Sync()
+	defer dstFd.Close()
+
+	ranges := src.Ranges()
+	for idx, rn := range ranges {
+		log.Printf("\tSplit Run %03d : Range Start From %v - Length: %v , IsSparse %v \n", idx, rn.Offset, rn.Length, rn.IsSparse)
+	}
+
+	if bufSize <= 0 {
+		bufSize = DefaultBufferSize
+	}
+	buf := make([]byte, bufSize)
+
+	hasSparse := false
+	for _, rn := range ranges {
+		if rn.IsSparse {
+			hasSparse = true
+			break
+		}
+	}
+	if hasSparse {
+		if err := markSparse(dstFd); err != nil {
+			log.Printf("\tcould not mark %s sparse (non-NTFS destination?): %v\n", dstfile, err)
+			hasSparse = false
+		}
+	}
+
+	started := time.Now()
+	lastLog := started
+	var written int64
+
+	reportProgress := func() {
+		if progress != nil {
+			progress(written, total)
+		}
+		if time.Since(lastLog) < progressLogInterval {
+			return
+		}
+		lastLog = time.Now()
+		elapsed := time.Since(started).Seconds()
+		if elapsed <= 0 {
+			return
+		}
+		mbps := float64(written) / (1 << 20) / elapsed
+		var eta time.Duration
+		if mbps > 0 && total > written {
+			eta = time.Duration(float64(total-written)/(1<<20)/mbps) * time.Second
+		}
+		log.Printf("\t%d/%d bytes copied, %.2f MB/s, ETA %s\n", written, total, mbps, eta)
+	}
+
+	if len(ranges) == 0 {
+		// go-ntfs resident/small streams may not report any runs; fall back
+		// to a single sequential copy of the whole stream.
+		convertedReader := ConvertFromReaderAtToReader(src, 0)
+		for {
+			n, rerr := convertedReader.Read(buf)
+			if n > 0 {
+				if _, werr := dstFd.Write(buf[:n]); werr != nil {
+					return werr
+				}
+				written += int64(n)
+				reportProgress()
+			}
+			if rerr != nil {
+				if rerr == io.EOF {
+					break
+				}
+				return rerr
+			}
+		}
+	} else {
+		for _, rn := range ranges {
+			rangeEnd := rn.Offset + rn.Length
+
+			if rn.IsSparse && hasSparse {
+				if err := dstFd.Truncate(rangeEnd); err != nil {
+					return err
+				}
+				if err := zeroRange(dstFd, rn.Offset, rangeEnd); err != nil {
+					return err
+				}
+				written += rn.Length
+				reportProgress()
+				continue
+			}
+
+			if _, err := dstFd.Seek(rn.Offset, io.SeekStart); err != nil {
+				return err
+			}
+			remaining := rn.Length
+			offset := rn.Offset
+			for remaining > 0 {
+				chunk := buf
+				if int64(len(chunk)) > remaining {
+					chunk = chunk[:remaining]
+				}
+				n, rerr := src.ReadAt(chunk, offset)
+				if n > 0 {
+					if _, werr := dstFd.Write(chunk[:n]); werr != nil {
+						return werr
+					}
+					offset += int64(n)
+					remaining -= int64(n)
+					written += int64(n)
+					reportProgress()
+				}
+				if rerr != nil {
+					if rerr == io.EOF && n > 0 {
+						continue
+					}
+					return rerr
+				}
+			}
+		}
+	}
+
+	log.Printf("Written %d Bytes to Destination Done. \n", written)
+	return nil
+}
+
+// ApplyOriginalMetadata restores the source file's creation/access/write
+// times onto the copied destination file via the Windows-specific
+// SetFileTime API, since the standard library's os package has no portable
+// way to set creation time.
+func ApplyOriginalMetadata(path string, info *ntfs.STANDARD_INFORMATION, dst string) error {
+	winFileHd, err := windows.Open(dst, windows.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	defer windows.CloseHandle(winFileHd)
+
+	cTime4Win := windows.NsecToFiletime(info.Create_time().UnixNano())
+	aTime4Win := windows.NsecToFiletime(info.File_accessed_time().UnixNano())
+	mTime4Win := windows.NsecToFiletime(info.File_altered_time().UnixNano())
+	return windows.SetFileTime(winFileHd, &cTime4Win, &aTime4Win, &mTime4Win)
+}
+
+// readerFromRangedReaderAt adapts an io.ReaderAt (such as go-ntfs's
+// RangeReaderAt) into a sequential io.Reader starting at a given offset.
+type readerFromRangedReaderAt struct {
+	r      io.ReaderAt
+	offset int64
+}
+
+func ConvertFromReaderAtToReader(r io.ReaderAt, o int64) *readerFromRangedReaderAt {
+	return &readerFromRangedReaderAt{r: r, offset: o}
+}
+
+func (rd *readerFromRangedReaderAt) Read(b []byte) (n int, err error) {
+	n, err = rd.r.ReadAt(b, rd.offset)
+	if n > 0 {
+		rd.offset += int64(n)
+	}
+	return
+}
@@ -0,0 +1,200 @@
+//go:build windows
+
+package rawcopy
+
+import (
+	"log"
+
+	"github.com/kmahyyg/go-rawcopy/internal/wof"
+
+	ntfs "www.velocidex.com/golang/go-ntfs/parser"
+)
+
+const (
+	NTFSAttrType_Data         = 128
+	NTFSAttrType_ReparsePoint = 192
+	NTFSAttrID_Normal         = 0
+)
+
+// File is a resolved MFT entry, positioned to have its metadata inspected
+// and its data streams extracted.
+type File struct {
+	vol      *Volume
+	entry    *ntfs.MFT_ENTRY
+	info     *ntfs.STANDARD_INFORMATION
+	fullPath string
+}
+
+// Metadata is the flattened, UI-friendly view of a File's
+// STANDARD_INFORMATION plus its full path and stream inventory, as printed
+// by the CLI's single-file mode (the -list-only inventory uses
+// InventoryRecord instead).
+type Metadata struct {
+	FullPath string
+	Size     int64
+	CTime    string
+	MTime    string
+	MFTMTime string
+	ATime    string
+	Streams  []Stream
+}
+
+// Stat returns the file's metadata, including its data stream inventory.
+func (f *File) Stat() *Metadata {
+	return &Metadata{
+		FullPath: f.vol.diskLetter + "/" + f.fullPath,
+		Size:     int64(f.info.Size()),
+		CTime:    f.info.Create_time().String(),
+		MTime:    f.info.File_altered_time().String(),
+		MFTMTime: f.info.Mft_altered_time().String(),
+		ATime:    f.info.File_accessed_time().String(),
+		Streams:  f.DataStreams(),
+	}
+}
+
+// DataStreams enumerates every type-128 $DATA attribute on the entry; the
+// stream with an empty Name is the primary, unnamed stream, every other one
+// is an alternate data stream.
+func (f *File) DataStreams() []Stream {
+	var streams []Stream
+	for _, attr := range f.entry.EnumerateAttributes(f.vol.ctx) {
+		if attr.Type().Value != NTFSAttrType_Data {
+			continue
+		}
+		streams = append(streams, Stream{
+			Name:  attr.Name(),
+			Id:    attr.Attribute_id(),
+			Size:  attr.DataSize(),
+			vol:   f.vol,
+			entry: f.entry,
+		})
+	}
+	return streams
+}
+
+// wofInfo returns the parsed WOF reparse info if this file is compressed
+// via compact.exe /EXE, or nil otherwise.
+func (f *File) wofInfo() *wof.ReparseInfo {
+	reparseReader, err := ntfs.OpenStream(f.vol.ctx, f.entry, NTFSAttrType_ReparsePoint, NTFSAttrID_Normal)
+	if err != nil {
+		return nil
+	}
+
+	header := make([]byte, 8)
+	if _, err := reparseReader.ReadAt(header, 0); err != nil {
+		return nil
+	}
+	tag := uint32(header[0]) | uint32(header[1])<<8 | uint32(header[2])<<16 | uint32(header[3])<<24
+	if tag != wof.IOReparseTagWOF {
+		return nil
+	}
+	dataLen := uint16(header[4]) | uint16(header[5])<<8
+
+	body := make([]byte, dataLen)
+	if _, err := reparseReader.ReadAt(body, 8); err != nil {
+		return nil
+	}
+
+	info, err := wof.ParseReparseBuffer(body)
+	if err != nil {
+		return nil
+	}
+	return info
+}
+
+// ADSMode controls how named $DATA streams (alternate data streams) are
+// handled by ExtractTo.
+type ADSMode string
+
+const (
+	ADSModeSkip    ADSMode = "skip"
+	ADSModeSidecar ADSMode = "sidecar"
+	ADSModeNative  ADSMode = "native"
+)
+
+// OutputFormat selects the on-disk layout ExtractTo produces.
+type OutputFormat string
+
+const (
+	// FormatRaw writes the primary stream (and ADS, per ADSMode) as plain
+	// files. This is the default.
+	FormatRaw OutputFormat = "raw"
+	// FormatBackup writes a single BackupRead-style WIN32_STREAM_ID
+	// container carrying $DATA, every ADS, the reparse point and the
+	// security descriptor. See writeBackupContainer.
+	FormatBackup OutputFormat = "backup"
+)
+
+// ExtractOptions configures File.ExtractTo.
+type ExtractOptions struct {
+	ADSMode ADSMode
+	Format  OutputFormat
+	// RestoreMetadata applies the original creation/access/write times to
+	// the primary destination file after copying, via ApplyOriginalMetadata;
+	// sidecar ADS files keep their copy-time timestamps.
+	RestoreMetadata bool
+	// BufferSize is the io.CopyBuffer buffer size; 0 selects DefaultBufferSize.
+	BufferSize int
+	// Progress, if non-nil, is invoked periodically during the copy with
+	// the bytes written so far and the stream's total size.
+	Progress func(written, total int64)
+}
+
+// DefaultBufferSize is used by ExtractTo when ExtractOptions.BufferSize is 0.
+const DefaultBufferSize = 1 << 20 // 1 MiB, a multiple of any NTFS cluster size
+
+// ExtractTo writes the file's data to dst. If the file is WOF-compressed it
+// is transparently decompressed; otherwise its primary $DATA (and, per
+// opts.ADSMode, every alternate data stream) is copied through
+// CopyToDestinationFile. When opts.RestoreMetadata is set, original
+// timestamps are applied afterwards.
+func (f *File) ExtractTo(dst string, opts ExtractOptions) error {
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = DefaultBufferSize
+	}
+	if opts.ADSMode == "" {
+		opts.ADSMode = ADSModeSidecar
+	}
+	if opts.Format == "" {
+		opts.Format = FormatRaw
+	}
+
+	if opts.Format == FormatBackup {
+		if err := writeBackupContainer(f.vol, f.entry, dst); err != nil {
+			return err
+		}
+		if opts.RestoreMetadata {
+			return ApplyOriginalMetadata(f.vol.diskLetter+"/"+f.fullPath, f.info, dst)
+		}
+		return nil
+	}
+
+	if info := f.wofInfo(); info != nil {
+		log.Printf("File is WOF-compressed with %s, decompressing WofCompressedData.\n", info.Algorithm)
+		if err := extractWOFCompressed(f.vol.ctx, f.entry, info, int64(f.info.Size()), dst, opts); err != nil {
+			return err
+		}
+	} else {
+		for _, s := range f.DataStreams() {
+			target := dst
+			if s.Name != "" {
+				if opts.ADSMode == ADSModeSkip {
+					continue
+				}
+				target = adsTargetPath(dst, s.Name, opts.ADSMode)
+			}
+			reader := s.RangeReaderAt()
+			if reader == nil {
+				return ErrReturnedNil
+			}
+			if err := CopyToDestinationFile(reader, target, s.Size, opts.BufferSize, opts.Progress); err != nil {
+				return err
+			}
+		}
+	}
+
+	if opts.RestoreMetadata {
+		return ApplyOriginalMetadata(f.vol.diskLetter+"/"+f.fullPath, f.info, dst)
+	}
+	return nil
+}
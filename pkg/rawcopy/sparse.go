@@ -0,0 +1,49 @@
+//go:build windows
+
+package rawcopy
+
+import (
+	"os"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// FSCTL codes and FILE_ZERO_DATA_INFORMATION are not exposed by
+// golang.org/x/sys/windows, so they're defined here directly from the
+// Windows DDK headers.
+const (
+	fsctlSetSparse   = 0x900C4
+	fsctlSetZeroData = 0x980C8
+)
+
+// fileZeroDataInformation mirrors FILE_ZERO_DATA_INFORMATION, the input
+// buffer for FSCTL_SET_ZERO_DATA: the byte range [FileOffset,
+// BeyondFinalZero) is deallocated and will read back as zeros.
+type fileZeroDataInformation struct {
+	FileOffset      int64
+	BeyondFinalZero int64
+}
+
+// markSparse flags dst as a sparse file via FSCTL_SET_SPARSE. Failure is
+// non-fatal: non-NTFS destinations (or filesystems without sparse file
+// support) simply keep writing zeros literally.
+func markSparse(dst *os.File) error {
+	var bytesReturned uint32
+	return windows.DeviceIoControl(windows.Handle(dst.Fd()), fsctlSetSparse, nil, 0, nil, 0, &bytesReturned, nil)
+}
+
+// zeroRange deallocates [start, end) on dst via FSCTL_SET_ZERO_DATA so the
+// range reads back as zeros without occupying disk space, assuming dst was
+// already marked sparse.
+func zeroRange(dst *os.File, start, end int64) error {
+	info := fileZeroDataInformation{FileOffset: start, BeyondFinalZero: end}
+	var bytesReturned uint32
+	return windows.DeviceIoControl(
+		windows.Handle(dst.Fd()),
+		fsctlSetZeroData,
+		(*byte)(unsafe.Pointer(&info)),
+		uint32(unsafe.Sizeof(info)),
+		nil, 0, &bytesReturned, nil,
+	)
+}
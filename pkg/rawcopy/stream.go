@@ -0,0 +1,45 @@
+//go:build windows
+
+package rawcopy
+
+import (
+	"fmt"
+
+	ntfs "www.velocidex.com/golang/go-ntfs/parser"
+)
+
+// Stream describes one type-128 $DATA attribute on a File: the unnamed
+// stream (Name == "") is the file's primary content, every other one is an
+// alternate data stream.
+type Stream struct {
+	Name string
+	Id   uint16
+	Size int64
+
+	vol   *Volume
+	entry *ntfs.MFT_ENTRY
+}
+
+// RangeReaderAt opens the stream for reading. It returns nil if the
+// attribute can no longer be opened (e.g. the entry changed underneath
+// us); callers should treat that the same as ErrReturnedNil.
+func (s Stream) RangeReaderAt() ntfs.RangeReaderAt {
+	reader, err := ntfs.OpenStream(s.vol.ctx, s.entry, NTFSAttrType_Data, s.Id)
+	if err != nil {
+		return nil
+	}
+	return reader
+}
+
+// adsTargetPath builds the destination path for an alternate data stream
+// given the primary output path, per ADSMode: "sidecar" appends
+// ".<name>.ads" for non-NTFS destinations, "native" uses Win32 colon
+// notation so the stream lands on the destination file itself.
+func adsTargetPath(dst, name string, mode ADSMode) string {
+	switch mode {
+	case ADSModeNative:
+		return fmt.Sprintf("%s:%s", dst, name)
+	default:
+		return fmt.Sprintf("%s.%s.ads", dst, name)
+	}
+}
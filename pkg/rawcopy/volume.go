@@ -0,0 +1,112 @@
+//go:build windows
+
+// Package rawcopy is the importable core of go-rawcopy: it opens an NTFS
+// volume through its raw device handle, walks the MFT to resolve files by
+// path, and extracts their data streams (including WOF-compressed and
+// alternate data streams) without going through the normal filesystem API.
+// cmd/go-rawcopy is a thin CLI wrapper around this package.
+package rawcopy
+
+import (
+	"errors"
+	"os"
+	"regexp"
+	"strings"
+
+	ntfs "www.velocidex.com/golang/go-ntfs/parser"
+)
+
+var (
+	ErrReturnedNil        = errors.New("result returned nil reference")
+	ErrInvalidInput       = errors.New("invalid input")
+	ErrDeviceInaccessible = errors.New("raw device is not accessible")
+	ErrCorruptData        = errors.New("on-disk structure failed validation")
+)
+
+var isDiskLetter = regexp.MustCompile(`^[a-zA-Z]:$`).MatchString
+
+// Volume wraps the raw device handle for one NTFS volume together with the
+// pagedReader and NTFSContext built on top of it. Everything else in this
+// package (File, Stream, bulk scanning) is reached through a Volume.
+type Volume struct {
+	diskLetter string
+	fd         *os.File
+	ctx        *ntfs.NTFSContext
+	root       *ntfs.MFT_ENTRY
+}
+
+// OpenVolume opens the raw device behind a drive letter (e.g. "C:") via its
+// UNC path, so the volume can be read even while files on it are locked by
+// other processes, and builds the NTFSContext used for every subsequent
+// lookup.
+func OpenVolume(diskLetter string) (*Volume, error) {
+	if !isDiskLetter(diskLetter) {
+		return nil, ErrInvalidInput
+	}
+
+	fd, err := os.Open("\\\\.\\" + diskLetter)
+	if err != nil {
+		return nil, ErrDeviceInaccessible
+	}
+
+	// page 4096 (one NTFS cluster), cache 65536 bytes - see package docs in main.go history
+	pagedReader, err := ntfs.NewPagedReader(fd, 0x1000, 0x10000)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, err := ntfs.GetNTFSContext(pagedReader, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := ctx.GetMFT(5)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Volume{diskLetter: diskLetter, fd: fd, ctx: ctx, root: root}, nil
+}
+
+// Close releases the underlying raw device handle.
+func (v *Volume) Close() error {
+	return v.fd.Close()
+}
+
+// Open resolves a volume-relative path (slash-separated, no leading slash)
+// to a File. Use EnsureNTFSPath/ToNTFSRelativePath to turn a
+// "C:\\foo\\bar" style path into (diskLetter, relPath) first.
+func (v *Volume) Open(path string) (*File, error) {
+	entry, err := v.root.Open(v.ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := entry.StandardInformation(v.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	fullPath := ntfs.GetFullPath(v.ctx, entry)
+
+	return &File{vol: v, entry: entry, info: info, fullPath: fullPath}, nil
+}
+
+// MFTEntry returns the raw MFT entry for a given record index, used by the
+// bulk $MFT scanner.
+func (v *Volume) MFTEntry(idx int64) (*ntfs.MFT_ENTRY, error) {
+	return v.ctx.GetMFT(idx)
+}
+
+// EnsureNTFSPath splits a Windows-style volume path ("C:\\foo\\bar") into
+// its backslash-separated components, the first of which is the drive
+// letter.
+func EnsureNTFSPath(volFilePath string) []string {
+	return strings.Split(volFilePath, "\\")
+}
+
+// ToNTFSRelativePath joins path components (as returned by EnsureNTFSPath,
+// minus the drive letter) into the forward-slash form go-ntfs expects.
+func ToNTFSRelativePath(components []string) string {
+	return strings.Join(components, "//")
+}
@@ -0,0 +1,111 @@
+//go:build windows
+
+package rawcopy
+
+import (
+	"errors"
+	"io"
+	"log"
+	"os"
+
+	"github.com/kmahyyg/go-rawcopy/internal/wof"
+
+	ntfs "www.velocidex.com/golang/go-ntfs/parser"
+)
+
+var ErrNoWofCompressedDataStream = errors.New("WofCompressedData attribute not found")
+
+// findNamedStream locates a type-128 $DATA attribute by name, the way
+// easy.go's GetDataForPath does, and opens it via OpenStream so multi-VCN
+// streams are still read as one contiguous reader.
+func findNamedStream(ntfsVolCtx *ntfs.NTFSContext, entry *ntfs.MFT_ENTRY, name string) (ntfs.RangeReaderAt, *ntfs.NTFS_ATTRIBUTE, error) {
+	for _, attr := range entry.EnumerateAttributes(ntfsVolCtx) {
+		if attr.Type().Value == NTFSAttrType_Data && attr.Name() == name {
+			reader, err := ntfs.OpenStream(ntfsVolCtx, entry, NTFSAttrType_Data, attr.Attribute_id())
+			if err != nil {
+				return nil, nil, err
+			}
+			return reader, attr, nil
+		}
+	}
+	return nil, nil, ErrNoWofCompressedDataStream
+}
+
+// extractWOFCompressed reads the WofCompressedData ADS attached to entry,
+// decompresses it chunk by chunk per info, and writes the logical
+// (decompressed) bytes to dst. uncompressedSize is taken from
+// STANDARD_INFORMATION rather than the reparse buffer, which does not
+// reliably carry it for every provider version.
+func extractWOFCompressed(ntfsVolCtx *ntfs.NTFSContext, entry *ntfs.MFT_ENTRY, info *wof.ReparseInfo, uncompressedSize int64, dst string, opts ExtractOptions) error {
+	wofStream, wofAttr, err := findNamedStream(ntfsVolCtx, entry, "WofCompressedData")
+	if err != nil {
+		return err
+	}
+	streamSize := wofAttr.DataSize()
+
+	chunkSize := info.Algorithm.ChunkSize()
+	if chunkSize == 0 {
+		return wof.ErrUnsupportedAlgorithm
+	}
+	numChunks := wof.ParseChunkTable(streamSize, uncompressedSize, info.Algorithm)
+	if numChunks == 0 {
+		return wof.ErrUnsupportedAlgorithm
+	}
+
+	// chunk table is (numChunks-1) little-endian uint32 offsets, measured
+	// from the first byte after the table; chunk 0 starts there too.
+	tableBytes := make([]byte, 4*(numChunks-1))
+	if numChunks > 1 {
+		if _, err := wofStream.ReadAt(tableBytes, 0); err != nil && err != io.EOF {
+			return err
+		}
+	}
+	tableLen := int64(len(tableBytes))
+
+	offsets := make([]int64, numChunks+1)
+	for i := 1; i < numChunks; i++ {
+		o := uint32(tableBytes[4*(i-1)]) | uint32(tableBytes[4*(i-1)+1])<<8 |
+			uint32(tableBytes[4*(i-1)+2])<<16 | uint32(tableBytes[4*(i-1)+3])<<24
+		offsets[i] = int64(o)
+	}
+	offsets[numChunks] = streamSize - tableLen
+
+	dstFd, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer dstFd.Close()
+
+	remaining := uncompressedSize
+	var written int64
+	for i := 0; i < numChunks; i++ {
+		start := tableLen + offsets[i]
+		end := tableLen + offsets[i+1]
+
+		logicalSize := int64(chunkSize)
+		if remaining < logicalSize {
+			logicalSize = remaining
+		}
+
+		compressed := make([]byte, end-start)
+		if _, err := wofStream.ReadAt(compressed, start); err != nil && err != io.EOF {
+			return err
+		}
+
+		plain, err := wof.DecompressChunk(info.Algorithm, compressed, int(logicalSize))
+		if err != nil {
+			return err
+		}
+		if _, err := dstFd.Write(plain); err != nil {
+			return err
+		}
+		remaining -= logicalSize
+		written += logicalSize
+		if opts.Progress != nil {
+			opts.Progress(written, uncompressedSize)
+		}
+	}
+
+	log.Printf("Decompressed %s stream, wrote %d bytes.\n", info.Algorithm, uncompressedSize)
+	return nil
+}